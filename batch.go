@@ -0,0 +1,48 @@
+package sticky
+
+import (
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// CutNDrawBatch は複数画像に対してCutNDrawをワーカープールで並列実行します。
+// 1画像あたりの処理はCPUバウンドかつ画像間で独立しているため、
+// WithWorkersで指定した数まで並列化して処理時間を短縮します。
+func CutNDrawBatch(imgs []gocv.Mat, opts ...Option) ([]Sticky, error) {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	workers := cfg.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]Sticky, len(imgs))
+	errs := make([]error, len(imgs))
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := range imgs {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s, err := CutNDraw(imgs[i], opts...)
+			results[i] = s
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
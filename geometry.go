@@ -0,0 +1,62 @@
+package sticky
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"gocv.io/x/gocv"
+)
+
+// orderRotatedRectPoints はgocv.RotatedRectの4頂点を
+// 左上→右上→右下→左下の順に並び替えます。
+// PaddleOCRのDB後処理(get_mini_boxes)と同様に、まずx座標でソートして
+// 左右2点ずつのペアに分け、それぞれのペアをy座標で上下に分けることで
+// 順序を決定します。
+func orderRotatedRectPoints(rect gocv.RotatedRect) []image.Point {
+	pts := make([]image.Point, len(rect.Contour))
+	copy(pts, rect.Contour)
+	sort.Slice(pts, func(i, j int) bool {
+		return pts[i].X < pts[j].X
+	})
+	left, right := pts[0:2], pts[2:4]
+	if left[0].Y > left[1].Y {
+		left[0], left[1] = left[1], left[0]
+	}
+	if right[0].Y > right[1].Y {
+		right[0], right[1] = right[1], right[0]
+	}
+
+	// left[0]=左上, right[0]=右上, right[1]=右下, left[1]=左下
+	return []image.Point{left[0], right[0], right[1], left[1]}
+}
+
+// cropRotated はquad(左上→右上→右下→左下の順の4点)で囲まれた領域を
+// 射影変換で長方形に正立化して切り出します。出力サイズはquadの
+// 辺の実測長から決定するため、傾いた付箋もまっすぐな画像になります。
+func cropRotated(img gocv.Mat, quad []image.Point) gocv.Mat {
+	w := int(math.Round(pointDistance(quad[0], quad[1])))
+	h := int(math.Round(pointDistance(quad[1], quad[2])))
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := []image.Point{{0, 0}, {w, 0}, {w, h}, {0, h}}
+	m := gocv.GetPerspectiveTransform(quad, dst)
+	defer m.Close()
+
+	warped := gocv.NewMat()
+	gocv.WarpPerspective(img, &warped, m, image.Pt(w, h))
+
+	return warped
+}
+
+// pointDistance は2点間のユークリッド距離を返却します。
+func pointDistance(a, b image.Point) float64 {
+	dx := float64(a.X - b.X)
+	dy := float64(a.Y - b.Y)
+	return math.Sqrt(dx*dx + dy*dy)
+}
@@ -0,0 +1,41 @@
+package sticky
+
+import (
+	"image"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestOrderRotatedRectPoints(t *testing.T) {
+	// 左上→右上→右下→左下を期待する正解
+	wantTL := image.Pt(0, 0)
+	wantTR := image.Pt(10, 0)
+	wantBR := image.Pt(10, 5)
+	wantBL := image.Pt(0, 5)
+
+	tests := []struct {
+		name    string
+		contour []image.Point
+	}{
+		{"alreadyOrdered", []image.Point{wantTL, wantTR, wantBR, wantBL}},
+		{"reversed", []image.Point{wantBL, wantBR, wantTR, wantTL}},
+		{"rotatedStart", []image.Point{wantBR, wantBL, wantTL, wantTR}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rect := gocv.RotatedRect{Contour: tt.contour}
+			got := orderRotatedRectPoints(rect)
+			want := []image.Point{wantTL, wantTR, wantBR, wantBL}
+			if len(got) != len(want) {
+				t.Fatalf("len(got) = %d, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("got[%d] = %v, want %v (full: %v)", i, got[i], want[i], got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package sticky
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	clipper "github.com/ctessum/go.clipper"
+)
+
+// nonMaxSuppression はIoU(および包含率)に基づき重複する検出矩形を間引きます。
+// 面積の大きいものから優先的に残し、それとIoUがiouThreshを超える、あるいは
+// 包含率(重なり面積/小さい方の面積)がcontainmentThreshを超える矩形を
+// 抑制します。gocvには回転矩形同士の交差領域を求めるAPIが無いため、
+// unclip.goで使っているgithub.com/ctessum/go.clipperのCtIntersectionで
+// 交差領域を求めます。
+func nonMaxSuppression(quads [][]image.Point, iouThresh, containmentThresh float64) [][]image.Point {
+	if len(quads) == 0 {
+		return nil
+	}
+
+	areas := make([]float64, len(quads))
+	order := make([]int, len(quads))
+	for i, q := range quads {
+		areas[i] = polygonArea(q)
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return areas[order[a]] > areas[order[b]]
+	})
+
+	suppressed := make([]bool, len(quads))
+	var kept []int
+	for _, i := range order {
+		if suppressed[i] {
+			continue
+		}
+		kept = append(kept, i)
+		for _, j := range order {
+			if j == i || suppressed[j] {
+				continue
+			}
+			inter := intersectionArea(quads[i], quads[j])
+			if inter <= 0 {
+				continue
+			}
+			iou := inter / (areas[i] + areas[j] - inter)
+			containment := inter / math.Min(areas[i], areas[j])
+			if iou > iouThresh || containment > containmentThresh {
+				suppressed[j] = true
+			}
+		}
+	}
+
+	result := make([][]image.Point, 0, len(kept))
+	for _, i := range kept {
+		result = append(result, quads[i])
+	}
+	return result
+}
+
+// intersectionArea はaとbで囲まれた領域同士が重なる面積を返却します。
+func intersectionArea(a, b []image.Point) float64 {
+	c := clipper.NewClipper(clipper.IoNone)
+	c.AddPath(toClipperPath(a), clipper.PtSubject, true)
+	c.AddPath(toClipperPath(b), clipper.PtClip, true)
+	solution, ok := c.Execute1(clipper.CtIntersection, clipper.PftNonZero, clipper.PftNonZero)
+	if !ok || len(solution) == 0 {
+		return 0
+	}
+
+	var area float64
+	for _, path := range solution {
+		area += math.Abs(clipper.Area(path))
+	}
+	return area
+}
@@ -0,0 +1,208 @@
+package sticky
+
+import (
+	"image/color"
+	"runtime"
+
+	"gocv.io/x/gocv"
+)
+
+// colorSpace はChannelSpecが参照する色空間を表します。
+// BGRはgocvが入力として受け取る色空間そのものなので変換不要です。
+type colorSpace struct {
+	name            string
+	code            gocv.ColorConversionCode
+	needsConversion bool
+}
+
+// ChannelSpec は色空間と、その中の1チャンネルを指定します。
+// BGR.G、YUV.U のように色空間名にチャンネル名を続けて参照します。
+type ChannelSpec struct {
+	label    string
+	space    colorSpace
+	index    int
+	pipeline []func(gocv.Mat) gocv.Mat
+}
+
+// String はデバッグ出力などで使うラベル(例: "YUV.U")を返却します。
+func (c ChannelSpec) String() string {
+	return c.label
+}
+
+// WithPipeline はこのチャンネルにのみ適用する前処理列を指定したChannelSpecの
+// コピーを返却します。指定しない場合はconfigのpipeline(既定はdefaultPreprocessPipeline)が
+// 使われるため、G/U/Vなどチャンネルごとに異なる前処理を割り当てたい場合に使います。
+func (c ChannelSpec) WithPipeline(pipeline ...func(gocv.Mat) gocv.Mat) ChannelSpec {
+	c.pipeline = pipeline
+	return c
+}
+
+// extract はimg(BGR)からChannelSpecが指す1チャンネルを取り出します。
+func (c ChannelSpec) extract(img gocv.Mat) gocv.Mat {
+	src := img
+	if c.space.needsConversion {
+		converted := img.Clone()
+		gocv.CvtColor(converted, &converted, c.space.code)
+		defer converted.Close()
+		src = converted
+	}
+	channels := gocv.Split(src)
+	out := channels[c.index]
+	for i, ch := range channels {
+		if i != c.index {
+			ch.Close()
+		}
+	}
+	return out
+}
+
+var (
+	bgrSpace = colorSpace{name: "BGR"}
+	yuvSpace = colorSpace{name: "YUV", code: gocv.ColorBGRToYUV, needsConversion: true}
+	labSpace = colorSpace{name: "LAB", code: gocv.ColorBGRToLab, needsConversion: true}
+	hsvSpace = colorSpace{name: "HSV", code: gocv.ColorBGRToHSV, needsConversion: true}
+)
+
+// BGR はBGR色空間のチャンネルを参照するための名前空間です。
+var BGR = struct{ B, G, R ChannelSpec }{
+	B: ChannelSpec{label: "BGR.B", space: bgrSpace, index: 0},
+	G: ChannelSpec{label: "BGR.G", space: bgrSpace, index: 1},
+	R: ChannelSpec{label: "BGR.R", space: bgrSpace, index: 2},
+}
+
+// YUV はYUV色空間のチャンネルを参照するための名前空間です。
+var YUV = struct{ Y, U, V ChannelSpec }{
+	Y: ChannelSpec{label: "YUV.Y", space: yuvSpace, index: 0},
+	U: ChannelSpec{label: "YUV.U", space: yuvSpace, index: 1},
+	V: ChannelSpec{label: "YUV.V", space: yuvSpace, index: 2},
+}
+
+// LAB はLAB色空間のチャンネルを参照するための名前空間です。
+var LAB = struct{ L, A, B ChannelSpec }{
+	L: ChannelSpec{label: "LAB.L", space: labSpace, index: 0},
+	A: ChannelSpec{label: "LAB.A", space: labSpace, index: 1},
+	B: ChannelSpec{label: "LAB.B", space: labSpace, index: 2},
+}
+
+// HSV はHSV色空間のチャンネルを参照するための名前空間です。
+var HSV = struct{ H, S, V ChannelSpec }{
+	H: ChannelSpec{label: "HSV.H", space: hsvSpace, index: 0},
+	S: ChannelSpec{label: "HSV.S", space: hsvSpace, index: 1},
+	V: ChannelSpec{label: "HSV.V", space: hsvSpace, index: 2},
+}
+
+// config はCutNDrawの挙動を調整するパラメータ一式です。
+// ゼロ値では使わず、必ずdefaultConfigで初期化してからOptionを適用します。
+type config struct {
+	minSideRatio      float64
+	aspectRatioThresh float64
+	iouThresh         float64
+	containmentThresh float64
+	adaptiveBlockSize int
+	adaptiveC         float64
+	channels          []ChannelSpec
+	drawColor         color.RGBA
+	debugSink         func(name string, m gocv.Mat)
+	thumbnailMaxDim   int
+	workers           int
+	unclipRatio       float64
+	pipeline          []func(gocv.Mat) gocv.Mat
+}
+
+// defaultConfig はCutNDrawの既定パラメータを返却します。
+func defaultConfig() config {
+	return config{
+		minSideRatio:      0.2,
+		aspectRatioThresh: 1.6,
+		iouThresh:         0.3,
+		containmentThresh: 0.8,
+		adaptiveBlockSize: 51,
+		adaptiveC:         1,
+		channels:          []ChannelSpec{BGR.G, YUV.U, YUV.V},
+		drawColor:         color.RGBA{255, 0, 0, 255},
+		workers:           runtime.NumCPU(),
+		unclipRatio:       defaultUnclipRatio,
+		pipeline:          defaultPreprocessPipeline(),
+	}
+}
+
+// Option はCutNDrawの挙動を調整する関数オプションです。
+type Option func(*config)
+
+// WithMinSideRatio は検出する矩形の最小辺長を、画像の短辺に対する比率で指定します。
+func WithMinSideRatio(ratio float64) Option {
+	return func(c *config) { c.minSideRatio = ratio }
+}
+
+// WithAspectRatio は検出する矩形のアスペクト比(長辺/短辺)の上限を指定します。
+func WithAspectRatio(thresh float64) Option {
+	return func(c *config) { c.aspectRatioThresh = thresh }
+}
+
+// WithMergeOverlap は重複検出のNMSで矩形を抑制するIoUの閾値を指定します。
+// IoUがこの値を超える矩形のうち、面積の小さい方を抑制します。
+func WithMergeOverlap(iouThresh float64) Option {
+	return func(c *config) { c.iouThresh = iouThresh }
+}
+
+// WithContainmentOverlap はNMSにおける包含率(重なり面積/小さい方の面積)の
+// 閾値を指定します。一方がもう一方にほぼ包含される場合を捉えるための閾値で、
+// IoUだけでは見逃すケースを補います。
+func WithContainmentOverlap(containmentThresh float64) Option {
+	return func(c *config) { c.containmentThresh = containmentThresh }
+}
+
+// WithAdaptiveThreshold は適応的二値化のブロックサイズと定数Cを指定します。
+func WithAdaptiveThreshold(blockSize int, c float64) Option {
+	return func(cfg *config) {
+		cfg.adaptiveBlockSize = blockSize
+		cfg.adaptiveC = c
+	}
+}
+
+// WithChannels は検出に使うチャンネル群を指定します。
+// 既定値はBGR.G, YUV.U, YUV.Vです。
+func WithChannels(specs ...ChannelSpec) Option {
+	return func(c *config) { c.channels = specs }
+}
+
+// WithDrawColor はDrawedImgに描画する矩形の色を指定します。
+func WithDrawColor(col color.RGBA) Option {
+	return func(c *config) { c.drawColor = col }
+}
+
+// WithDebugSink は各チャンネルの前処理後のマスク画像を受け取るコールバックを指定します。
+// 新しいカメラやホワイトボードに合わせてパラメータを調整する際に有用です。
+// 渡されるMatはCutNDraw内部で使う実体とは別のクローンなので、sink側で
+// 保持・破棄を自由に行えます(不要になったらClose()してください)。
+func WithDebugSink(sink func(name string, m gocv.Mat)) Option {
+	return func(c *config) { c.debugSink = sink }
+}
+
+// WithThumbnail はCroppedImgsごとにThumbnailsを生成するようにします。
+// 生成されるサムネイルはアスペクト比を保ったまま長辺がmaxDim以下になります。
+// OCRサービスへの送信やギャラリーUI構築など、呼び出し側での縮小処理の
+// 重複実装を避けるためのものです。
+func WithThumbnail(maxDim int) Option {
+	return func(c *config) { c.thumbnailMaxDim = maxDim }
+}
+
+// WithWorkers はCutNDrawBatchが使うワーカープールのサイズを指定します。
+// 既定値はruntime.NumCPU()です。
+func WithWorkers(workers int) Option {
+	return func(c *config) { c.workers = workers }
+}
+
+// WithUnclipRatio はunclipで検出矩形を外側へ広げる際の比率を指定します。
+// 既定値はdefaultUnclipRatio(0.2)で、0.15〜0.2程度が目安です。
+func WithUnclipRatio(ratio float64) Option {
+	return func(c *config) { c.unclipRatio = ratio }
+}
+
+// WithPipeline はチャンネルに適用する前処理列を指定します。個々のChannelSpecで
+// ChannelSpec.WithPipelineが指定されている場合はそちらが優先され、このOptionは
+// 指定のないチャンネルの既定値として使われます。sigmoidコントラスト以外にも
+// CLAHEやバイラテラルフィルタなど任意の処理を差し込めます。
+func WithPipeline(pipeline ...func(gocv.Mat) gocv.Mat) Option {
+	return func(c *config) { c.pipeline = pipeline }
+}
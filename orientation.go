@@ -0,0 +1,98 @@
+package sticky
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"gocv.io/x/gocv"
+)
+
+// CutNDrawFromReader はrから画像を読み込み、JPEGのEXIF Orientationタグに
+// 従って向きを補正したうえでCutNDrawを実行します。スマートフォンで撮影した
+// 写真はEXIFの向き情報がないと横倒しや天地逆になって渡ってくるため、
+// DrawedImg・CroppedImgsはここで常に正立した状態になります。
+func CutNDrawFromReader(r io.Reader, opts ...Option) (Sticky, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Sticky{}, err
+	}
+
+	orientation := readExifOrientation(data)
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Sticky{}, err
+	}
+
+	mat, err := gocv.ImageToMatRGBA(decoded)
+	if err != nil {
+		return Sticky{}, err
+	}
+	defer mat.Close()
+	gocv.CvtColor(mat, &mat, gocv.ColorRGBAToBGR)
+	applyExifOrientation(&mat, orientation)
+
+	result, err := CutNDraw(mat, opts...)
+	if err != nil {
+		return Sticky{}, err
+	}
+	result.AppliedOrientation = orientation
+
+	return result, nil
+}
+
+// readExifOrientation はJPEGのEXIFからOrientationタグ(1〜8)を読み取ります。
+// EXIFが存在しない、またはタグがない場合は無補正を表す1を返します。
+func readExifOrientation(data []byte) int {
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 1
+	}
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return 1
+	}
+	v, err := tag.Int(0)
+	if err != nil || v < 1 || v > 8 {
+		return 1
+	}
+	return v
+}
+
+// applyExifOrientation はEXIF Orientationタグの値(1〜8)に従いmatを
+// 回転・反転させ、画面に表示した際の見た目を正立させます。
+// transpose/rotateは90度・270度回転時に幅と高さが入れ替わるため、src/dstに
+// 同じMatを渡すとOpenCV側で結果が壊れます。そのためreplace経由で必ず
+// 新しいMatへ書き込み、古いMatを閉じてから差し替えます。
+func applyExifOrientation(mat *gocv.Mat, orientation int) {
+	switch orientation {
+	case 2:
+		replace(mat, func(src gocv.Mat, dst *gocv.Mat) { gocv.Flip(src, dst, 1) }) // 左右反転
+	case 3:
+		replace(mat, func(src gocv.Mat, dst *gocv.Mat) { gocv.Rotate(src, dst, gocv.Rotate180Clockwise) })
+	case 4:
+		replace(mat, func(src gocv.Mat, dst *gocv.Mat) { gocv.Flip(src, dst, 0) }) // 上下反転
+	case 5:
+		replace(mat, func(src gocv.Mat, dst *gocv.Mat) { gocv.Transpose(src, dst) }) // 左上-右下の軸で反転
+	case 6:
+		replace(mat, func(src gocv.Mat, dst *gocv.Mat) { gocv.Rotate(src, dst, gocv.Rotate90Clockwise) })
+	case 7:
+		replace(mat, func(src gocv.Mat, dst *gocv.Mat) { gocv.Transpose(src, dst) })
+		replace(mat, func(src gocv.Mat, dst *gocv.Mat) { gocv.Rotate(src, dst, gocv.Rotate180Clockwise) })
+	case 8:
+		replace(mat, func(src gocv.Mat, dst *gocv.Mat) { gocv.Rotate(src, dst, gocv.Rotate90CounterClockwise) })
+	}
+}
+
+// replace はmatを入力として新しいMatへopを適用し、古いmatを閉じてから
+// *matを結果に差し替えます。
+func replace(mat *gocv.Mat, op func(src gocv.Mat, dst *gocv.Mat)) {
+	dst := gocv.NewMat()
+	op(*mat, &dst)
+	mat.Close()
+	*mat = dst
+}
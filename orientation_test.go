@@ -0,0 +1,21 @@
+package sticky
+
+import "testing"
+
+func TestReadExifOrientationFallsBackToOneWithoutExif(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"notAnImage", []byte("not a jpeg")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readExifOrientation(tt.data); got != 1 {
+				t.Errorf("readExifOrientation(%q) = %d, want 1", tt.data, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package sticky
+
+import (
+	"math"
+
+	"gocv.io/x/gocv"
+)
+
+// defaultSigmoidMidpoint, defaultSigmoidFactor はsigmoidコントラストの既定値です。
+const (
+	defaultSigmoidMidpoint = 0.5
+	defaultSigmoidFactor   = 5.0
+)
+
+// defaultPreprocessPipeline はCutNDrawが各チャンネルに適用する既定の前処理列です。
+func defaultPreprocessPipeline() []func(gocv.Mat) gocv.Mat {
+	return []func(gocv.Mat) gocv.Mat{
+		sigmoidContrastStep(defaultSigmoidMidpoint, defaultSigmoidFactor),
+	}
+}
+
+// sigmoidContrastStep はsigmoidによるコントラスト強調を行う前処理ステップを返却します。
+// 直線的なLUTと異なりハイライト・シャドウの階調を保ったまま
+// midpoint付近のコントラストを強調できるため、光沢のあるホワイトボード上の
+// パステルカラーの付箋でも階調が潰れにくくなります。
+func sigmoidContrastStep(midpoint, factor float64) func(gocv.Mat) gocv.Mat {
+	return func(mat gocv.Mat) gocv.Mat {
+		lut := sigmoidContrastLUT(midpoint, factor)
+		defer lut.Close()
+		out := gocv.NewMat()
+		gocv.LUT(mat, lut, &out)
+		return out
+	}
+}
+
+// sigmoidContrastCurve はsigmoidコントラスト変換の生値(正規化前)を返します。
+func sigmoidContrastCurve(midpoint, factor, in float64) float64 {
+	return 1.0 / (1.0 + math.Exp(factor*(midpoint-in)))
+}
+
+// sigmoidContrastLUT はsigmoidコントラスト変換の256階調分のLUTを生成します。
+func sigmoidContrastLUT(midpoint, factor float64) gocv.Mat {
+	lut, _ := gocv.NewMatFromBytes(1, 256, gocv.MatTypeCV8U, sigmoidContrastLUTBytes(midpoint, factor))
+	return lut
+}
+
+// sigmoidContrastLUTBytes はsigmoidContrastLUTが使う256階調分の生バイト列を計算します。
+// sigmoidContrastCurveの出力はin=0でもbaseline分だけ底上げされ、in=1でも
+// 1に届かないため、baseline(in=0の値)とspan(in=1の値との差)で正規化して
+// 0〜255の全域を使い切るようにし、AdjustSigmoidの標準的な画像調整レシピに倣います。
+func sigmoidContrastLUTBytes(midpoint, factor float64) []byte {
+	baseline := sigmoidContrastCurve(midpoint, factor, 0.0)
+	span := sigmoidContrastCurve(midpoint, factor, 1.0) - baseline
+	lutBytes := make([]byte, 256)
+	for i := 0; i < 256; i++ {
+		in := float64(i) / 255.0
+		v := (sigmoidContrastCurve(midpoint, factor, in) - baseline) / span
+		scaled := v * 255.0
+		switch {
+		case scaled < 0:
+			scaled = 0
+		case scaled > 255:
+			scaled = 255
+		}
+		lutBytes[i] = byte(scaled)
+	}
+	return lutBytes
+}
+
+// preprocessingImg はpipelineの各ステップを順に適用したのち、
+// 指定したブロックサイズ・定数Cで適応的二値化を行った画像を返却します。
+func preprocessingImg(img gocv.Mat, pipeline []func(gocv.Mat) gocv.Mat, blockSize int, c float64) gocv.Mat {
+	processed := img.Clone()
+	for _, step := range pipeline {
+		next := step(processed)
+		processed.Close()
+		processed = next
+	}
+	gocv.AdaptiveThreshold(processed, &processed, 255,
+		gocv.AdaptiveThresholdGaussian,
+		gocv.ThresholdBinaryInv, blockSize, float32(c))
+
+	return processed
+}
@@ -0,0 +1,35 @@
+package sticky
+
+import "testing"
+
+func TestSigmoidContrastLUTBytesSpansFullRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		midpoint float64
+		factor   float64
+	}{
+		{"default", defaultSigmoidMidpoint, defaultSigmoidFactor},
+		{"steep", 0.5, 12.0},
+		{"shiftedMidpoint", 0.3, 5.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lut := sigmoidContrastLUTBytes(tt.midpoint, tt.factor)
+			if len(lut) != 256 {
+				t.Fatalf("len(lut) = %d, want 256", len(lut))
+			}
+			if lut[0] != 0 {
+				t.Errorf("lut[0] = %d, want 0", lut[0])
+			}
+			if lut[255] != 255 {
+				t.Errorf("lut[255] = %d, want 255", lut[255])
+			}
+			for i := 1; i < len(lut); i++ {
+				if lut[i] < lut[i-1] {
+					t.Errorf("lut is not monotonic at i=%d: lut[%d]=%d > lut[%d]=%d", i, i-1, lut[i-1], i, lut[i])
+				}
+			}
+		})
+	}
+}
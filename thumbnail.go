@@ -0,0 +1,35 @@
+package sticky
+
+import (
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// makeThumbnail はsrcの長辺がmaxDim以下になるようアスペクト比を保って
+// 縮小します。縮小時はモアレの少ないgocv.InterpolationAreaを使用します。
+func makeThumbnail(src gocv.Mat, maxDim int) gocv.Mat {
+	w, h := src.Cols(), src.Rows()
+	longSide := w
+	if h > longSide {
+		longSide = h
+	}
+	if longSide <= maxDim || longSide == 0 {
+		return src.Clone()
+	}
+
+	scale := float64(maxDim) / float64(longSide)
+	newW := int(float64(w) * scale)
+	newH := int(float64(h) * scale)
+	if newW < 1 {
+		newW = 1
+	}
+	if newH < 1 {
+		newH = 1
+	}
+
+	thumb := gocv.NewMat()
+	gocv.Resize(src, &thumb, image.Pt(newW, newH), 0, 0, gocv.InterpolationArea)
+
+	return thumb
+}
@@ -0,0 +1,74 @@
+package sticky
+
+import (
+	"image"
+	"math"
+
+	clipper "github.com/ctessum/go.clipper"
+	"gocv.io/x/gocv"
+)
+
+// defaultUnclipRatio はunclipで矩形を外側へ広げる際の既定の比率です。
+const defaultUnclipRatio = 0.2
+
+// unclip は検出した四角形pointsをratioに応じて外側へ広げます。
+// DB(Differentiable Binarization)のテキスト検出で使われるVatti/Clipper
+// 由来のオフセット手法と同様に、多角形の面積Aと周囲長Lに基づき
+// d = A * ratio / L だけ各辺の法線方向に膨らませます。
+// 実際のオフセット計算はgithub.com/ctessum/go.clipperのOffsetPolygons
+// 相当の処理(ClipperOffset.Execute)にJtRound/EtClosedPolygonで委譲し、
+// 得られた輪郭の最小外接回転矩形を左上→右上→右下→左下の順で返却します。
+func unclip(points []image.Point, ratio float64) []image.Point {
+	area := polygonArea(points)
+	perimeter := polygonPerimeter(points)
+	if perimeter == 0 {
+		return points
+	}
+	d := area * ratio / perimeter
+
+	co := clipper.NewClipperOffset()
+	co.AddPath(toClipperPath(points), clipper.JtRound, clipper.EtClosedPolygon)
+	solution := co.Execute(d)
+	if len(solution) == 0 || len(solution[0]) < 3 {
+		return points
+	}
+
+	expanded := make([]image.Point, len(solution[0]))
+	for i, p := range solution[0] {
+		expanded[i] = image.Pt(int(p.X), int(p.Y))
+	}
+
+	return orderRotatedRectPoints(gocv.MinAreaRect(expanded))
+}
+
+// toClipperPath はimage.Pointの多角形をclipper.Pathへ変換します。
+func toClipperPath(points []image.Point) clipper.Path {
+	path := make(clipper.Path, len(points))
+	for i, p := range points {
+		path[i] = &clipper.IntPoint{X: clipper.CInt(p.X), Y: clipper.CInt(p.Y)}
+	}
+	return path
+}
+
+// polygonArea はshoelace公式を用いて多角形の面積を計算します。
+func polygonArea(points []image.Point) float64 {
+	var area float64
+	n := len(points)
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		area += float64(points[i].X)*float64(points[j].Y) -
+			float64(points[j].X)*float64(points[i].Y)
+	}
+	return math.Abs(area) / 2
+}
+
+// polygonPerimeter は多角形の周囲長を計算します。
+func polygonPerimeter(points []image.Point) float64 {
+	var perimeter float64
+	n := len(points)
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		perimeter += pointDistance(points[i], points[j])
+	}
+	return perimeter
+}
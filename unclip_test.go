@@ -0,0 +1,46 @@
+package sticky
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestPolygonArea(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []image.Point
+		want   float64
+	}{
+		{"unitSquare", []image.Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, 1},
+		{"rectangle", []image.Point{{0, 0}, {10, 0}, {10, 5}, {0, 5}}, 50},
+		{"rightTriangle", []image.Point{{0, 0}, {4, 0}, {0, 3}}, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := polygonArea(tt.points); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("polygonArea(%v) = %v, want %v", tt.points, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygonPerimeter(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []image.Point
+		want   float64
+	}{
+		{"unitSquare", []image.Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, 4},
+		{"rectangle", []image.Point{{0, 0}, {10, 0}, {10, 5}, {0, 5}}, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := polygonPerimeter(tt.points); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("polygonPerimeter(%v) = %v, want %v", tt.points, got, tt.want)
+			}
+		})
+	}
+}